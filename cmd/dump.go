@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/idahoakl/go-i2c"
+	"github.com/spf13/cobra"
+	"log"
+)
+
+func init() {
+	RootCmd.AddCommand(dumpCmd)
+}
+
+var dumpCmd = &cobra.Command{
+	Use:  "dump",
+	Args: cobra.RangeArgs(2, 4),
+	Run: func(cmd *cobra.Command, args []string) {
+		bus := parseInt(args[0])
+		addr := parseInt(args[1])
+
+		first, last := 0x00, 0xFF
+		if len(args) > 2 {
+			first = parseInt(args[2])
+		}
+		if len(args) > 3 {
+			last = parseInt(args[3])
+		}
+
+		i2C, e := i2c.NewI2C(bus)
+		if e != nil {
+			log.Fatal(e)
+		}
+		defer i2C.Close()
+
+		for row := first - first%16; row <= last; row += 16 {
+			buf := make([]byte, 16)
+			for i := range buf {
+				reg := row + i
+				if reg < first || reg > last {
+					continue
+				}
+				b, e := i2C.ReadRegU8(uint8(addr), byte(reg))
+				if e != nil {
+					log.Fatal(e)
+				}
+				buf[i] = b
+			}
+
+			fmt.Printf("%02x: ", row)
+			for i, b := range buf {
+				reg := row + i
+				if reg < first || reg > last {
+					fmt.Print("   ")
+				} else {
+					fmt.Printf("%02x ", b)
+				}
+			}
+
+			fmt.Print(" ")
+			for i, b := range buf {
+				reg := row + i
+				if reg < first || reg > last {
+					fmt.Print(" ")
+					continue
+				}
+				if b >= 0x20 && b < 0x7F {
+					fmt.Printf("%c", b)
+				} else {
+					fmt.Print(".")
+				}
+			}
+			fmt.Println()
+		}
+	},
+}
@@ -11,7 +11,7 @@ var RootCmd = &cobra.Command{
 }
 
 func parseInt(s string) int {
-	if i, e := strconv.ParseInt(s, 0, 8); e != nil {
+	if i, e := strconv.ParseInt(s, 0, 0); e != nil {
 		log.Fatal(e)
 		return -1
 	} else {
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/idahoakl/go-i2c"
+	"github.com/spf13/cobra"
+	"log"
+)
+
+func init() {
+	RootCmd.AddCommand(scanCmd)
+}
+
+var scanCmd = &cobra.Command{
+	Use:  "scan",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		bus := parseInt(args[0])
+
+		i2C, e := i2c.NewI2C(bus)
+		if e != nil {
+			log.Fatal(e)
+		}
+		defer i2C.Close()
+
+		fmt.Print("     0  1  2  3  4  5  6  7  8  9  a  b  c  d  e  f\n")
+		for row := 0; row < 0x78; row += 16 {
+			fmt.Printf("%02x: ", row)
+			for col := 0; col < 16; col++ {
+				addr := row + col
+				if addr < 0x03 || addr > 0x77 {
+					fmt.Print("   ")
+					continue
+				}
+				if probe(i2C, uint8(addr)) {
+					fmt.Printf("%02x ", addr)
+				} else {
+					fmt.Print("-- ")
+				}
+			}
+			fmt.Println()
+		}
+	},
+}
+
+// probe follows the conventions of the kernel i2cdetect tool: addresses
+// in ranges that are sensitive to writes are probed with a one byte
+// read instead of a zero-length SMBus quick write.
+func probe(i2C i2c.Bus, addr uint8) bool {
+	if (addr >= 0x30 && addr <= 0x37) || (addr >= 0x50 && addr <= 0x5F) {
+		var buf [1]byte
+		return i2C.Tx(addr, nil, buf[:]) == nil
+	}
+	return i2C.Tx(addr, nil, nil) == nil
+}
@@ -0,0 +1,71 @@
+package i2c
+
+import "strings"
+
+// Funcs is the functionality bitmask an adapter reports via the
+// I2C_FUNCS ioctl. See (*I2C).Functionality.
+type Funcs uint64
+
+// Functionality bits, mirroring <linux/i2c.h>.
+const (
+	FuncI2C                 Funcs = 0x00000001
+	FuncTenBitAddr          Funcs = 0x00000002
+	FuncProtocolMangling    Funcs = 0x00000004
+	FuncSMBusPEC            Funcs = 0x00000008
+	FuncNoStart             Funcs = 0x00000010
+	FuncSlave               Funcs = 0x00000020
+	FuncSMBusBlockProcCall  Funcs = 0x00008000
+	FuncSMBusQuick          Funcs = 0x00010000
+	FuncSMBusReadByte       Funcs = 0x00020000
+	FuncSMBusWriteByte      Funcs = 0x00040000
+	FuncSMBusReadByteData   Funcs = 0x00080000
+	FuncSMBusWriteByteData  Funcs = 0x00100000
+	FuncSMBusReadWordData   Funcs = 0x00200000
+	FuncSMBusWriteWordData  Funcs = 0x00400000
+	FuncSMBusProcCall       Funcs = 0x00800000
+	FuncSMBusReadBlockData  Funcs = 0x01000000
+	FuncSMBusWriteBlockData Funcs = 0x02000000
+	FuncSMBusReadI2CBlock   Funcs = 0x04000000
+	FuncSMBusWriteI2CBlock  Funcs = 0x08000000
+)
+
+var funcNames = []struct {
+	bit  Funcs
+	name string
+}{
+	{FuncI2C, "I2C"},
+	{FuncTenBitAddr, "10BIT_ADDR"},
+	{FuncProtocolMangling, "PROTOCOL_MANGLING"},
+	{FuncSMBusPEC, "SMBUS_PEC"},
+	{FuncNoStart, "NOSTART"},
+	{FuncSlave, "SLAVE"},
+	{FuncSMBusBlockProcCall, "SMBUS_BLOCK_PROC_CALL"},
+	{FuncSMBusQuick, "SMBUS_QUICK"},
+	{FuncSMBusReadByte, "SMBUS_READ_BYTE"},
+	{FuncSMBusWriteByte, "SMBUS_WRITE_BYTE"},
+	{FuncSMBusReadByteData, "SMBUS_READ_BYTE_DATA"},
+	{FuncSMBusWriteByteData, "SMBUS_WRITE_BYTE_DATA"},
+	{FuncSMBusReadWordData, "SMBUS_READ_WORD_DATA"},
+	{FuncSMBusWriteWordData, "SMBUS_WRITE_WORD_DATA"},
+	{FuncSMBusProcCall, "SMBUS_PROC_CALL"},
+	{FuncSMBusReadBlockData, "SMBUS_READ_BLOCK_DATA"},
+	{FuncSMBusWriteBlockData, "SMBUS_WRITE_BLOCK_DATA"},
+	{FuncSMBusReadI2CBlock, "SMBUS_READ_I2C_BLOCK"},
+	{FuncSMBusWriteI2CBlock, "SMBUS_WRITE_I2C_BLOCK"},
+}
+
+// String renders f as a pipe separated list of its set bits, for logging.
+// Only the bits named above are listed; other set bits are left out, and
+// "NONE" is returned when none of the named bits is set.
+func (f Funcs) String() string {
+	var names []string
+	for _, fn := range funcNames {
+		if f&fn.bit != 0 {
+			names = append(names, fn.name)
+		}
+	}
+	if len(names) == 0 {
+		return "NONE"
+	}
+	return strings.Join(names, "|")
+}
@@ -9,20 +9,93 @@
 package i2c
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"runtime"
 	"syscall"
 	"sync"
+	"unsafe"
 )
 
 const (
-	I2C_SLAVE = 0x0703
+	I2C_SLAVE  = 0x0703
+	I2C_TENBIT = 0x0704
+	I2C_FUNCS  = 0x0705
+	I2C_RDWR   = 0x0707
 )
 
+// Message flags for Msg.Flags, mirroring <linux/i2c.h>.
+const (
+	MsgRead    = 0x0001 // this is a read message
+	MsgTen     = 0x0010 // this is a ten bit chip address
+	MsgNoStart = 0x4000 // don't send a START before this message
+	MsgStop    = 0x8000 // force a STOP after this message
+)
+
+// ErrRdwrNotSupported is returned by Tx and Transfer when the adapter's
+// reported functionality bits don't include I2C_FUNC_I2C, so callers can
+// fall back to a plain Write followed by Read.
+var ErrRdwrNotSupported = errors.New("i2c: adapter does not support I2C_RDWR")
+
+// ErrTenBitNotSupported is returned by the Ten variants of Tx/Write/Read
+// when the adapter's reported functionality bits don't include
+// I2C_FUNC_10BIT_ADDR.
+var ErrTenBitNotSupported = errors.New("i2c: adapter does not support 10-bit addressing")
+
+// Msg is a single message of a combined I2C_RDWR transaction. See Transfer.
+type Msg struct {
+	Addr  uint16
+	Flags uint16
+	Buf   []byte
+}
+
+// i2cMsg mirrors struct i2c_msg from <linux/i2c.h>.
+type i2cMsg struct {
+	addr  uint16
+	flags uint16
+	len   uint16
+	buf   uintptr
+}
+
+// i2cRdwrIoctlData mirrors struct i2c_rdwr_ioctl_data from <linux/i2c-dev.h>.
+type i2cRdwrIoctlData struct {
+	msgs  uintptr
+	nmsgs uint32
+}
+
+// Bus is the subset of *I2C that driver authors should depend on, so
+// drivers can be built and tested against other implementations such as
+// the in-memory bus in the tester package.
+type Bus interface {
+	Read(addr uint8, p []byte) (int, error)
+	Write(addr uint8, buf []byte) (int, error)
+	Tx(addr uint8, w, r []byte) error
+	Transfer(msgs []Msg) error
+	TxTen(addr uint16, w, r []byte) error
+
+	ReadRegU8(addr uint8, reg byte) (byte, error)
+	WriteRegU8(addr uint8, reg byte, value byte) error
+
+	ReadRegU16BE(addr uint8, reg byte) (uint16, error)
+	ReadRegU16LE(addr uint8, reg byte) (uint16, error)
+	ReadRegS16BE(addr uint8, reg byte) (int16, error)
+	ReadRegS16LE(addr uint8, reg byte) (int16, error)
+	WriteRegU16BE(addr uint8, reg byte, value uint16) error
+	WriteRegU16LE(addr uint8, reg byte, value uint16) error
+	WriteRegS16BE(addr uint8, reg byte, value int16) error
+	WriteRegS16LE(addr uint8, reg byte, value int16) error
+
+	Functionality() (Funcs, error)
+
+	Close() error
+}
+
 // I2C represents a connection to an i2c device.
 type I2C struct {
 	rc *os.File
 	mtx *sync.Mutex
+	funcs Funcs
 }
 
 // New opens a connection to an i2c device.
@@ -36,6 +109,11 @@ func NewI2C(bus int) (*I2C, error) {
 		rc: f,
 		mtx: &sync.Mutex{},
 	}
+
+	if funcs, e := this.readFuncs(); e == nil {
+		this.funcs = funcs
+	}
+
 	return this, nil
 }
 
@@ -43,6 +121,23 @@ func (this *I2C) setAddress(addr uint8) error {
 	return ioctl(this.rc.Fd(), I2C_SLAVE, uintptr(addr))
 }
 
+// setAddressTen puts the adapter into 10-bit addressing mode via
+// I2C_TENBIT before setting addr with I2C_SLAVE. Needed for the plain
+// Write/Read path, which (unlike Transfer/Tx) has no per-call flag to
+// carry the addressing mode.
+func (this *I2C) setAddressTen(addr uint16) error {
+	if e := ioctl(this.rc.Fd(), I2C_TENBIT, 1); e != nil {
+		return e
+	}
+	return ioctl(this.rc.Fd(), I2C_SLAVE, uintptr(addr))
+}
+
+// clearAddressTen takes the adapter back out of 10-bit addressing mode,
+// since I2C_TENBIT is sticky on the file descriptor.
+func (this *I2C) clearAddressTen() error {
+	return ioctl(this.rc.Fd(), I2C_TENBIT, 0)
+}
+
 // Write sends buf to the remote i2c device. The interpretation of
 // the message is implementation dependant.
 func (this *I2C) Write(addr uint8, buf []byte) (int, error) {
@@ -54,12 +149,24 @@ func (this *I2C) Write(addr uint8, buf []byte) (int, error) {
 
 func (this *I2C) writeNoSync(addr uint8, buf []byte) (int, error) {
 	if e := this.setAddress(addr); e != nil {
-		return nil, e
+		return 0, e
 	}
 
 	return this.rc.Write(buf)
 }
 
+func (this *I2C) writeNoSyncTen(addr uint16, buf []byte) (int, error) {
+	if this.funcs&FuncTenBitAddr == 0 {
+		return 0, ErrTenBitNotSupported
+	}
+	if e := this.setAddressTen(addr); e != nil {
+		return 0, e
+	}
+	defer this.clearAddressTen()
+
+	return this.rc.Write(buf)
+}
+
 func (this *I2C) WriteByte(addr uint8, b byte) (int, error) {
 	this.mtx.Lock()
 	defer this.mtx.Unlock()
@@ -84,12 +191,23 @@ func (this *I2C) Read(addr uint8, p []byte) (int, error) {
 
 func (this *I2C) readNoSync(addr uint8, p []byte) (int, error) {
 	if e := this.setAddress(addr); e != nil {
-		return nil, e
+		return 0, e
 	}
 
 	return this.rc.Read(p)
 }
 
+func (this *I2C) readNoSyncTen(addr uint16, p []byte) (int, error) {
+	if this.funcs&FuncTenBitAddr == 0 {
+		return 0, ErrTenBitNotSupported
+	}
+	if e := this.setAddressTen(addr); e != nil {
+		return 0, e
+	}
+	defer this.clearAddressTen()
+
+	return this.rc.Read(p)
+}
 
 func (this *I2C) Close() error {
 	this.mtx.Lock()
@@ -98,25 +216,161 @@ func (this *I2C) Close() error {
 	return this.rc.Close()
 }
 
+// Transfer submits msgs to the kernel as a single atomic I2C_RDWR
+// transaction, so a repeated-START is issued between messages instead of
+// a STOP. Returns ErrRdwrNotSupported if the adapter doesn't advertise
+// I2C_FUNC_I2C.
+func (this *I2C) Transfer(msgs []Msg) error {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	return this.transferNoSync(msgs)
+}
+
+func (this *I2C) transferNoSync(msgs []Msg) error {
+	if this.funcs&FuncI2C == 0 {
+		return ErrRdwrNotSupported
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	for _, m := range msgs {
+		if m.Flags&MsgTen != 0 && this.funcs&FuncTenBitAddr == 0 {
+			return ErrTenBitNotSupported
+		}
+	}
+
+	raw := make([]i2cMsg, len(msgs))
+	for i, m := range msgs {
+		var buf uintptr
+		if len(m.Buf) > 0 {
+			buf = uintptr(unsafe.Pointer(&m.Buf[0]))
+		}
+		raw[i] = i2cMsg{
+			addr:  m.Addr,
+			flags: m.Flags,
+			len:   uint16(len(m.Buf)),
+			buf:   buf,
+		}
+	}
+
+	rdwr := i2cRdwrIoctlData{
+		msgs:  uintptr(unsafe.Pointer(&raw[0])),
+		nmsgs: uint32(len(raw)),
+	}
+	err := ioctl(this.rc.Fd(), I2C_RDWR, uintptr(unsafe.Pointer(&rdwr)))
+	runtime.KeepAlive(msgs)
+	runtime.KeepAlive(raw)
+	return err
+}
+
+// Tx submits a write of w immediately followed by a read into r as a
+// single I2C_RDWR transaction, so the kernel issues a repeated-START
+// between them rather than a STOP. Many sensors (BMP280, MPU-series,
+// EEPROMs with 16-bit register addresses) require this in place of a
+// plain Write followed by Read. Either w or r may be nil to submit a
+// single message.
+func (this *I2C) Tx(addr uint8, w, r []byte) error {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	return this.txNoSync(addr, w, r)
+}
+
+func (this *I2C) txNoSync(addr uint8, w, r []byte) error {
+	return this.txAddrNoSync(uint16(addr), 0, w, r)
+}
+
+// TxTen is the 10-bit addressing counterpart of Tx, for the handful of
+// adapters and devices that support I2C_FUNC_10BIT_ADDR. Returns
+// ErrTenBitNotSupported if the adapter doesn't advertise it.
+func (this *I2C) TxTen(addr uint16, w, r []byte) error {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	return this.txAddrNoSync(addr, MsgTen, w, r)
+}
+
+func (this *I2C) txAddrNoSync(addr uint16, flags uint16, w, r []byte) error {
+	var msgs []Msg
+	if len(w) > 0 {
+		msgs = append(msgs, Msg{Addr: addr, Flags: flags, Buf: w})
+	}
+	if len(r) > 0 {
+		msgs = append(msgs, Msg{Addr: addr, Flags: flags | MsgRead, Buf: r})
+	}
+	if len(msgs) == 0 {
+		// Tx(addr, nil, nil): a zero-length write, i.e. an SMBus quick
+		// write that only checks the address byte is ACKed.
+		msgs = append(msgs, Msg{Addr: addr, Flags: flags})
+	}
+	return this.transferNoSync(msgs)
+}
+
 // SMBus (System Management Bus) protocol over I2C.
 // Read byte from i2c device register specified in reg.
 func (this *I2C) ReadRegU8(addr uint8, reg byte) (byte, error) {
 	this.mtx.Lock()
 	defer this.mtx.Unlock()
 
-	_, err := this.writeNoSync(addr, []byte{reg})
-	if err != nil {
-		return 0, err
-	}
 	buf := make([]byte, 1)
-	_, err = this.readNoSync(addr, buf)
-	if err != nil {
+	if err := this.readRegNoSync(addr, []byte{reg}, buf); err != nil {
 		return 0, err
 	}
 	log.Debug("Read U8 %d from reg 0x%0X", buf[0], reg)
 	return buf[0], nil
 }
 
+// readRegNoSync writes the register pointer in w and reads the response
+// into buf, preferring a combined I2C_RDWR transaction and falling back
+// to a plain write followed by a read if the adapter doesn't support it.
+func (this *I2C) readRegNoSync(addr uint8, w, buf []byte) error {
+	return this.readRegAddrNoSync(uint16(addr), false, w, buf)
+}
+
+// readRegAddrNoSync is the uint16-addressed, optionally 10-bit form of
+// readRegNoSync shared by the plain and *Ten register helpers.
+func (this *I2C) readRegAddrNoSync(addr uint16, tenBit bool, w, buf []byte) error {
+	var flags uint16
+	if tenBit {
+		flags = MsgTen
+	}
+
+	err := this.txAddrNoSync(addr, flags, w, buf)
+	if err == nil {
+		return nil
+	}
+	if err != ErrRdwrNotSupported {
+		return err
+	}
+
+	if tenBit {
+		if _, err := this.writeNoSyncTen(addr, w); err != nil {
+			return err
+		}
+		_, err = this.readNoSyncTen(addr, buf)
+		return err
+	}
+
+	if _, err := this.writeNoSync(uint8(addr), w); err != nil {
+		return err
+	}
+	_, err = this.readNoSync(uint8(addr), buf)
+	return err
+}
+
+// writeRegAddrNoSync writes buf (reg followed by the value bytes) to
+// addr, preferring the 10-bit addressed path when tenBit is set.
+func (this *I2C) writeRegAddrNoSync(addr uint16, tenBit bool, buf []byte) error {
+	if tenBit {
+		_, err := this.writeNoSyncTen(addr, buf)
+		return err
+	}
+	_, err := this.writeNoSync(uint8(addr), buf)
+	return err
+}
+
 // SMBus (System Management Bus) protocol over I2C.
 // Write byte to i2c device register specified in reg.
 func (this *I2C) WriteRegU8(addr uint8, reg byte, value byte) error {
@@ -139,13 +393,8 @@ func (this *I2C) ReadRegU16BE(addr uint8, reg byte) (uint16, error) {
 	this.mtx.Lock()
 	defer this.mtx.Unlock()
 
-	_, err := this.writeNoSync(addr, []byte{reg})
-	if err != nil {
-		return 0, err
-	}
 	buf := make([]byte, 2)
-	_, err = this.readNoSync(addr, buf)
-	if err != nil {
+	if err := this.readRegNoSync(addr, []byte{reg}, buf); err != nil {
 		return 0, err
 	}
 	w := uint16(buf[0])<<8 + uint16(buf[1])
@@ -173,13 +422,8 @@ func (this *I2C) ReadRegS16BE(addr uint8, reg byte) (int16, error) {
 	this.mtx.Lock()
 	defer this.mtx.Unlock()
 
-	_, err := this.writeNoSync(addr, []byte{reg})
-	if err != nil {
-		return 0, err
-	}
 	buf := make([]byte, 2)
-	_, err = this.readNoSync(addr, buf)
-	if err != nil {
+	if err := this.readRegNoSync(addr, []byte{reg}, buf); err != nil {
 		return 0, err
 	}
 	w := int16(buf[0])<<8 + int16(buf[1])
@@ -221,7 +465,8 @@ func (this *I2C) WriteRegU16BE(addr uint8, reg byte, value uint16) error {
 // Write unsigned big endian word (16 bits) value to i2c device
 // starting from address specified in reg.
 func (this *I2C) WriteRegU16LE(addr uint8, reg byte, value uint16) error {
-	w := (value*0xFF00)>>8 + value<<8
+	// exchange bytes
+	w := (value&0xFF)<<8 + value>>8
 	return this.WriteRegU16BE(addr, reg, w)
 }
 
@@ -245,10 +490,31 @@ func (this *I2C) WriteRegS16BE(addr uint8, reg byte, value int16) error {
 // Write signed big endian word (16 bits) value to i2c device
 // starting from address specified in reg.
 func (this *I2C) WriteRegS16LE(addr uint8, reg byte, value int16) error {
-	w := int16((uint16(value)*0xFF00)>>8) + value<<8
-	return this.WriteRegS16BE(addr, reg, w)
+	// exchange bytes
+	u := uint16(value)
+	u = (u&0xFF)<<8 + u>>8
+	return this.WriteRegS16BE(addr, reg, int16(u))
 }
 
+// Functionality queries the adapter's supported functionality bits via
+// the I2C_FUNCS ioctl.
+func (this *I2C) Functionality() (Funcs, error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	return this.readFuncs()
+}
+
+func (this *I2C) readFuncs() (Funcs, error) {
+	var funcs uint64
+	if e := ioctl(this.rc.Fd(), I2C_FUNCS, uintptr(unsafe.Pointer(&funcs))); e != nil {
+		return 0, e
+	}
+	return Funcs(funcs), nil
+}
+
+var _ Bus = (*I2C)(nil)
+
 func ioctl(fd, cmd, arg uintptr) error {
 	_, _, err := syscall.Syscall6(syscall.SYS_IOCTL, fd, cmd, arg, 0, 0, 0)
 	if err != 0 {
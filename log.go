@@ -0,0 +1,17 @@
+package i2c
+
+import "fmt"
+
+// log is a minimal trace logger for low level read/write calls. Tracing
+// is off by default so normal use of the package stays quiet.
+var log = &traceLogger{}
+
+type traceLogger struct {
+	enabled bool
+}
+
+func (l *traceLogger) Debug(format string, args ...interface{}) {
+	if l.enabled {
+		fmt.Printf(format+"\n", args...)
+	}
+}
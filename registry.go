@@ -0,0 +1,135 @@
+package i2c
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BusRef describes one /dev/i2c-N character device discovered by All.
+type BusRef struct {
+	Bus  int
+	Name string
+}
+
+type refCountedI2C struct {
+	i2c      *I2C
+	refCount int
+}
+
+var (
+	registryMtx sync.Mutex
+	registry    = make(map[int]*refCountedI2C)
+	names       = make(map[string]int)
+)
+
+// RegisterName associates a symbolic name with a bus number, so later
+// callers can Open it by name instead of remembering the bus number.
+func RegisterName(name string, bus int) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	names[name] = bus
+}
+
+// Open returns a reference-counted handle to the i2c bus identified by
+// name, which may be a bus number ("1"), a device path ("/dev/i2c-1"),
+// or a symbolic name previously passed to RegisterName. Callers opening
+// the same bus concurrently share one underlying file descriptor, and it
+// is only closed once every Bus handed out for it has been closed. This
+// avoids two goroutines independently calling NewI2C on the same bus and
+// racing on I2C_SLAVE ioctls.
+func Open(name string) (Bus, error) {
+	bus, err := resolveBus(name)
+	if err != nil {
+		return nil, err
+	}
+
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	ref, ok := registry[bus]
+	if !ok {
+		dev, err := NewI2C(bus)
+		if err != nil {
+			return nil, err
+		}
+		ref = &refCountedI2C{i2c: dev}
+		registry[bus] = ref
+	}
+	ref.refCount++
+
+	return &sharedBus{I2C: ref.i2c, bus: bus}, nil
+}
+
+func resolveBus(name string) (int, error) {
+	registryMtx.Lock()
+	bus, ok := names[name]
+	registryMtx.Unlock()
+	if ok {
+		return bus, nil
+	}
+
+	bus, err := strconv.Atoi(strings.TrimPrefix(name, "/dev/i2c-"))
+	if err != nil {
+		return 0, fmt.Errorf("i2c: unknown bus %q", name)
+	}
+	return bus, nil
+}
+
+// sharedBus wraps a registry-owned *I2C so that Close releases this
+// handle's reference instead of closing the underlying file descriptor
+// outright.
+type sharedBus struct {
+	*I2C
+	bus int
+}
+
+var _ Bus = (*sharedBus)(nil)
+
+func (b *sharedBus) Close() error {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	ref, ok := registry[b.bus]
+	if !ok {
+		return nil
+	}
+
+	ref.refCount--
+	if ref.refCount > 0 {
+		return nil
+	}
+
+	delete(registry, b.bus)
+	return ref.i2c.Close()
+}
+
+// All enumerates the /dev/i2c-* character devices present on this system
+// and the kernel-reported name of each adapter.
+func All() ([]BusRef, error) {
+	matches, err := filepath.Glob("/dev/i2c-*")
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]BusRef, 0, len(matches))
+	for _, m := range matches {
+		bus, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(m), "i2c-"))
+		if err != nil {
+			continue
+		}
+
+		name := ""
+		if b, err := ioutil.ReadFile(fmt.Sprintf("/sys/class/i2c-dev/i2c-%d/name", bus)); err == nil {
+			name = strings.TrimSpace(string(b))
+		}
+
+		refs = append(refs, BusRef{Bus: bus, Name: name})
+	}
+
+	return refs, nil
+}
@@ -0,0 +1,41 @@
+package i2c
+
+import "testing"
+
+func TestResolveBusNumber(t *testing.T) {
+	bus, err := resolveBus("1")
+	if err != nil {
+		t.Fatalf("resolveBus: %v", err)
+	}
+	if bus != 1 {
+		t.Fatalf("got %d, want 1", bus)
+	}
+}
+
+func TestResolveBusDevicePath(t *testing.T) {
+	bus, err := resolveBus("/dev/i2c-3")
+	if err != nil {
+		t.Fatalf("resolveBus: %v", err)
+	}
+	if bus != 3 {
+		t.Fatalf("got %d, want 3", bus)
+	}
+}
+
+func TestResolveBusRegisteredName(t *testing.T) {
+	RegisterName("imu", 2)
+
+	bus, err := resolveBus("imu")
+	if err != nil {
+		t.Fatalf("resolveBus: %v", err)
+	}
+	if bus != 2 {
+		t.Fatalf("got %d, want 2", bus)
+	}
+}
+
+func TestResolveBusUnknownName(t *testing.T) {
+	if _, err := resolveBus("does-not-exist"); err == nil {
+		t.Fatal("expected an error for an unknown bus name")
+	}
+}
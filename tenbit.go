@@ -0,0 +1,166 @@
+package i2c
+
+// This file mirrors the plain 7-bit addressed methods on *I2C with 10-bit
+// addressed *Ten counterparts, for the handful of adapters and devices
+// that advertise I2C_FUNC_10BIT_ADDR. Each returns ErrTenBitNotSupported
+// if the adapter doesn't.
+
+// WriteTen is the 10-bit addressing counterpart of Write.
+func (this *I2C) WriteTen(addr uint16, buf []byte) (int, error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	return this.writeNoSyncTen(addr, buf)
+}
+
+// ReadTen is the 10-bit addressing counterpart of Read.
+func (this *I2C) ReadTen(addr uint16, p []byte) (int, error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	return this.readNoSyncTen(addr, p)
+}
+
+// SMBus (System Management Bus) protocol over I2C.
+// Read byte from i2c device register specified in reg. 10-bit addressing
+// counterpart of ReadRegU8.
+func (this *I2C) ReadRegU8Ten(addr uint16, reg byte) (byte, error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	buf := make([]byte, 1)
+	if err := this.readRegAddrNoSync(addr, true, []byte{reg}, buf); err != nil {
+		return 0, err
+	}
+	log.Debug("Read U8 %d from reg 0x%0X (10-bit addr 0x%03X)", buf[0], reg, addr)
+	return buf[0], nil
+}
+
+// SMBus (System Management Bus) protocol over I2C.
+// Write byte to i2c device register specified in reg. 10-bit addressing
+// counterpart of WriteRegU8.
+func (this *I2C) WriteRegU8Ten(addr uint16, reg byte, value byte) error {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	if err := this.writeRegAddrNoSync(addr, true, []byte{reg, value}); err != nil {
+		return err
+	}
+	log.Debug("Write U8 %d to reg 0x%0X (10-bit addr 0x%03X)", value, reg, addr)
+	return nil
+}
+
+// SMBus (System Management Bus) protocol over I2C.
+// Read unsigned big endian word (16 bits) from i2c device starting from
+// address specified in reg. 10-bit addressing counterpart of
+// ReadRegU16BE.
+func (this *I2C) ReadRegU16BETen(addr uint16, reg byte) (uint16, error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	buf := make([]byte, 2)
+	if err := this.readRegAddrNoSync(addr, true, []byte{reg}, buf); err != nil {
+		return 0, err
+	}
+	w := uint16(buf[0])<<8 + uint16(buf[1])
+	log.Debug("Read U16 %d from reg 0x%0X (10-bit addr 0x%03X)", w, reg, addr)
+	return w, nil
+}
+
+// SMBus (System Management Bus) protocol over I2C.
+// Read unsigned little endian word (16 bits) from i2c device starting
+// from address specified in reg. 10-bit addressing counterpart of
+// ReadRegU16LE.
+func (this *I2C) ReadRegU16LETen(addr uint16, reg byte) (uint16, error) {
+	w, err := this.ReadRegU16BETen(addr, reg)
+	if err != nil {
+		return 0, err
+	}
+	// exchange bytes
+	w = (w&0xFF)<<8 + w>>8
+	return w, nil
+}
+
+// SMBus (System Management Bus) protocol over I2C.
+// Read signed big endian word (16 bits) from i2c device starting from
+// address specified in reg. 10-bit addressing counterpart of
+// ReadRegS16BE.
+func (this *I2C) ReadRegS16BETen(addr uint16, reg byte) (int16, error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	buf := make([]byte, 2)
+	if err := this.readRegAddrNoSync(addr, true, []byte{reg}, buf); err != nil {
+		return 0, err
+	}
+	w := int16(buf[0])<<8 + int16(buf[1])
+	log.Debug("Read S16 %d from reg 0x%0X (10-bit addr 0x%03X)", w, reg, addr)
+	return w, nil
+}
+
+// SMBus (System Management Bus) protocol over I2C.
+// Read signed little endian word (16 bits) from i2c device starting
+// from address specified in reg. 10-bit addressing counterpart of
+// ReadRegS16LE.
+func (this *I2C) ReadRegS16LETen(addr uint16, reg byte) (int16, error) {
+	w, err := this.ReadRegS16BETen(addr, reg)
+	if err != nil {
+		return 0, err
+	}
+	// exchange bytes
+	w = (w&0xFF)<<8 + w>>8
+	return w, nil
+}
+
+// SMBus (System Management Bus) protocol over I2C.
+// Write unsigned big endian word (16 bits) value to i2c device starting
+// from address specified in reg. 10-bit addressing counterpart of
+// WriteRegU16BE.
+func (this *I2C) WriteRegU16BETen(addr uint16, reg byte, value uint16) error {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	buf := []byte{reg, byte((value & 0xFF00) >> 8), byte(value & 0xFF)}
+	if err := this.writeRegAddrNoSync(addr, true, buf); err != nil {
+		return err
+	}
+	log.Debug("Write U16 %d to reg 0x%0X (10-bit addr 0x%03X)", value, reg, addr)
+	return nil
+}
+
+// SMBus (System Management Bus) protocol over I2C.
+// Write unsigned little endian word (16 bits) value to i2c device
+// starting from address specified in reg. 10-bit addressing counterpart
+// of WriteRegU16LE.
+func (this *I2C) WriteRegU16LETen(addr uint16, reg byte, value uint16) error {
+	// exchange bytes
+	w := (value&0xFF)<<8 + value>>8
+	return this.WriteRegU16BETen(addr, reg, w)
+}
+
+// SMBus (System Management Bus) protocol over I2C.
+// Write signed big endian word (16 bits) value to i2c device starting
+// from address specified in reg. 10-bit addressing counterpart of
+// WriteRegS16BE.
+func (this *I2C) WriteRegS16BETen(addr uint16, reg byte, value int16) error {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	buf := []byte{reg, byte((uint16(value) & 0xFF00) >> 8), byte(value & 0xFF)}
+	if err := this.writeRegAddrNoSync(addr, true, buf); err != nil {
+		return err
+	}
+	log.Debug("Write S16 %d to reg 0x%0X (10-bit addr 0x%03X)", value, reg, addr)
+	return nil
+}
+
+// SMBus (System Management Bus) protocol over I2C.
+// Write signed little endian word (16 bits) value to i2c device starting
+// from address specified in reg. 10-bit addressing counterpart of
+// WriteRegS16LE.
+func (this *I2C) WriteRegS16LETen(addr uint16, reg byte, value int16) error {
+	// exchange bytes
+	u := uint16(value)
+	u = (u&0xFF)<<8 + u>>8
+	return this.WriteRegS16BETen(addr, reg, int16(u))
+}
@@ -0,0 +1,256 @@
+// Package tester provides an in-memory i2c.Bus implementation and a set
+// of pluggable fake devices, so drivers built on github.com/idahoakl/go-i2c
+// can be unit-tested without real hardware.
+package tester
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/idahoakl/go-i2c"
+)
+
+var _ i2c.Bus = (*Bus)(nil)
+
+// Call records one transaction dispatched by a Bus, so tests can assert
+// on the sequence of i2c traffic a driver produced.
+type Call struct {
+	Addr uint8
+	W    []byte
+	R    []byte
+}
+
+// Bus is an in-memory github.com/idahoakl/go-i2c.Bus that dispatches by
+// 7-bit address to registered Devices.
+type Bus struct {
+	mtx     sync.Mutex
+	devices map[uint8]Device
+	calls   []Call
+}
+
+// NewBus creates an empty mock Bus.
+func NewBus() *Bus {
+	return &Bus{devices: make(map[uint8]Device)}
+}
+
+// Register attaches d so the bus will dispatch transactions addressed to
+// d.Addr() to it. Registering a second device at the same address
+// replaces the first.
+func (b *Bus) Register(d Device) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.devices[d.Addr()] = d
+}
+
+// Calls returns the transactions dispatched so far, in order.
+func (b *Bus) Calls() []Call {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return append([]Call(nil), b.calls...)
+}
+
+func (b *Bus) device(addr uint8) (Device, error) {
+	d, ok := b.devices[addr]
+	if !ok {
+		return nil, fmt.Errorf("tester: no device registered at address 0x%02X", addr)
+	}
+	return d, nil
+}
+
+func (b *Bus) Write(addr uint8, buf []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	d, err := b.device(addr)
+	if err != nil {
+		return 0, err
+	}
+	b.calls = append(b.calls, Call{Addr: addr, W: buf})
+	if err := d.Tx(buf, nil); err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+func (b *Bus) Read(addr uint8, p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	d, err := b.device(addr)
+	if err != nil {
+		return 0, err
+	}
+	b.calls = append(b.calls, Call{Addr: addr, R: p})
+	if err := d.Tx(nil, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (b *Bus) Tx(addr uint8, w, r []byte) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	d, err := b.device(addr)
+	if err != nil {
+		return err
+	}
+	b.calls = append(b.calls, Call{Addr: addr, W: w, R: r})
+	return d.Tx(w, r)
+}
+
+// Transfer dispatches msgs, in order, to the devices registered at their
+// addresses. A write immediately followed by a read at the same address
+// is coalesced into a single Device.Tx call, mirroring the
+// repeated-START a real I2C_RDWR transaction gives the device.
+func (b *Bus) Transfer(msgs []i2c.Msg) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for i := 0; i < len(msgs); i++ {
+		m := msgs[i]
+		addr := uint8(m.Addr)
+		d, err := b.device(addr)
+		if err != nil {
+			return err
+		}
+
+		if m.Flags&i2c.MsgRead == 0 && i+1 < len(msgs) &&
+			msgs[i+1].Addr == m.Addr && msgs[i+1].Flags&i2c.MsgRead != 0 {
+			next := msgs[i+1]
+			b.calls = append(b.calls, Call{Addr: addr, W: m.Buf, R: next.Buf})
+			if err := d.Tx(m.Buf, next.Buf); err != nil {
+				return err
+			}
+			i++
+			continue
+		}
+
+		if m.Flags&i2c.MsgRead != 0 {
+			b.calls = append(b.calls, Call{Addr: addr, R: m.Buf})
+			if err := d.Tx(nil, m.Buf); err != nil {
+				return err
+			}
+		} else {
+			b.calls = append(b.calls, Call{Addr: addr, W: m.Buf})
+			if err := d.Tx(m.Buf, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// TxTen is the 10-bit addressing counterpart of Tx. The mock has no
+// concept of 10-bit vs 7-bit addressing, so it simply dispatches to the
+// device registered under the low 8 bits of addr.
+func (b *Bus) TxTen(addr uint16, w, r []byte) error {
+	return b.Tx(uint8(addr), w, r)
+}
+
+// Functionality reports a fixed set of bits covering everything the mock
+// implements (I2C_RDWR and 10-bit addressing), since it has no real
+// adapter to query.
+func (b *Bus) Functionality() (i2c.Funcs, error) {
+	return i2c.FuncI2C | i2c.FuncTenBitAddr, nil
+}
+
+func (b *Bus) readReg(addr uint8, reg byte, buf []byte) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	d, err := b.device(addr)
+	if err != nil {
+		return err
+	}
+	b.calls = append(b.calls, Call{Addr: addr, W: []byte{reg}, R: buf})
+	return d.ReadRegister(reg, buf)
+}
+
+func (b *Bus) writeReg(addr uint8, reg byte, buf []byte) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	d, err := b.device(addr)
+	if err != nil {
+		return err
+	}
+	b.calls = append(b.calls, Call{Addr: addr, W: append([]byte{reg}, buf...)})
+	return d.WriteRegister(reg, buf)
+}
+
+func (b *Bus) ReadRegU8(addr uint8, reg byte) (byte, error) {
+	buf := make([]byte, 1)
+	if err := b.readReg(addr, reg, buf); err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+func (b *Bus) WriteRegU8(addr uint8, reg byte, value byte) error {
+	return b.writeReg(addr, reg, []byte{value})
+}
+
+func (b *Bus) ReadRegU16BE(addr uint8, reg byte) (uint16, error) {
+	buf := make([]byte, 2)
+	if err := b.readReg(addr, reg, buf); err != nil {
+		return 0, err
+	}
+	return uint16(buf[0])<<8 + uint16(buf[1]), nil
+}
+
+func (b *Bus) ReadRegU16LE(addr uint8, reg byte) (uint16, error) {
+	w, err := b.ReadRegU16BE(addr, reg)
+	if err != nil {
+		return 0, err
+	}
+	// exchange bytes
+	w = (w&0xFF)<<8 + w>>8
+	return w, nil
+}
+
+func (b *Bus) ReadRegS16BE(addr uint8, reg byte) (int16, error) {
+	buf := make([]byte, 2)
+	if err := b.readReg(addr, reg, buf); err != nil {
+		return 0, err
+	}
+	return int16(buf[0])<<8 + int16(buf[1]), nil
+}
+
+func (b *Bus) ReadRegS16LE(addr uint8, reg byte) (int16, error) {
+	w, err := b.ReadRegS16BE(addr, reg)
+	if err != nil {
+		return 0, err
+	}
+	// exchange bytes
+	w = (w&0xFF)<<8 + w>>8
+	return w, nil
+}
+
+func (b *Bus) WriteRegU16BE(addr uint8, reg byte, value uint16) error {
+	return b.writeReg(addr, reg, []byte{byte((value & 0xFF00) >> 8), byte(value & 0xFF)})
+}
+
+func (b *Bus) WriteRegU16LE(addr uint8, reg byte, value uint16) error {
+	// exchange bytes
+	w := (value&0xFF)<<8 + value>>8
+	return b.WriteRegU16BE(addr, reg, w)
+}
+
+func (b *Bus) WriteRegS16BE(addr uint8, reg byte, value int16) error {
+	return b.writeReg(addr, reg, []byte{byte((uint16(value) & 0xFF00) >> 8), byte(value & 0xFF)})
+}
+
+func (b *Bus) WriteRegS16LE(addr uint8, reg byte, value int16) error {
+	// exchange bytes
+	u := uint16(value)
+	u = (u&0xFF)<<8 + u>>8
+	return b.WriteRegS16BE(addr, reg, int16(u))
+}
+
+// Close is a no-op; the mock bus holds no OS resources.
+func (b *Bus) Close() error {
+	return nil
+}
@@ -0,0 +1,152 @@
+package tester
+
+import (
+	"testing"
+
+	"github.com/idahoakl/go-i2c"
+)
+
+func TestBusDevice8RegisterRoundTrip(t *testing.T) {
+	bus := NewBus()
+	bus.Register(NewDevice8(0x40))
+
+	if err := bus.WriteRegU8(0x40, 0x01, 0xAB); err != nil {
+		t.Fatalf("WriteRegU8: %v", err)
+	}
+	got, err := bus.ReadRegU8(0x40, 0x01)
+	if err != nil {
+		t.Fatalf("ReadRegU8: %v", err)
+	}
+	if got != 0xAB {
+		t.Fatalf("got 0x%02X, want 0xAB", got)
+	}
+}
+
+func TestBusUnregisteredAddress(t *testing.T) {
+	bus := NewBus()
+
+	if _, err := bus.ReadRegU8(0x50, 0x00); err == nil {
+		t.Fatal("expected error reading from an unregistered address")
+	}
+}
+
+func TestBusWriteRegU16LERoundTrip(t *testing.T) {
+	bus := NewBus()
+	dev := NewDevice8(0x40)
+	bus.Register(dev)
+
+	if err := bus.WriteRegU16LE(0x40, 0x00, 0x1234); err != nil {
+		t.Fatalf("WriteRegU16LE: %v", err)
+	}
+	// 0x1234 little endian on the wire is low byte (0x34) then high byte
+	// (0x12), matching *i2c.I2C.WriteRegU16LE so the mock is a faithful
+	// stand-in for the real bus.
+	if dev.Get(0x00) != 0x34 || dev.Get(0x01) != 0x12 {
+		t.Fatalf("got [0x%02X 0x%02X], want [0x34 0x12]", dev.Get(0x00), dev.Get(0x01))
+	}
+	got, err := bus.ReadRegU16LE(0x40, 0x00)
+	if err != nil {
+		t.Fatalf("ReadRegU16LE: %v", err)
+	}
+	if got != 0x1234 {
+		t.Fatalf("got 0x%04X, want 0x1234", got)
+	}
+}
+
+func TestBusWriteRegS16LEWiresLowByteFirst(t *testing.T) {
+	bus := NewBus()
+	dev := NewDevice8(0x40)
+	bus.Register(dev)
+
+	// -2 is 0xFFFE; little endian on the wire is low byte (0xFE) then
+	// high byte (0xFF).
+	if err := bus.WriteRegS16LE(0x40, 0x00, -2); err != nil {
+		t.Fatalf("WriteRegS16LE: %v", err)
+	}
+	if dev.Get(0x00) != 0xFE || dev.Get(0x01) != 0xFF {
+		t.Fatalf("got [0x%02X 0x%02X], want [0xFE 0xFF]", dev.Get(0x00), dev.Get(0x01))
+	}
+}
+
+func TestDevice16TxRoundTrip(t *testing.T) {
+	bus := NewBus()
+	bus.Register(NewDevice16(0x50))
+
+	if err := bus.Tx(0x50, []byte{0x00, 0x10, 0xFF}, nil); err != nil {
+		t.Fatalf("Tx write: %v", err)
+	}
+	buf := make([]byte, 1)
+	if err := bus.Tx(0x50, []byte{0x00, 0x10}, buf); err != nil {
+		t.Fatalf("Tx read: %v", err)
+	}
+	if buf[0] != 0xFF {
+		t.Fatalf("got 0x%02X, want 0xFF", buf[0])
+	}
+}
+
+func TestCommandDeviceHandler(t *testing.T) {
+	bus := NewBus()
+	dev := NewCommandDevice(0x60)
+	dev.Handle([]byte{0x01}, []byte{0xDE, 0xAD})
+	bus.Register(dev)
+
+	buf := make([]byte, 2)
+	if err := bus.Tx(0x60, []byte{0x01}, buf); err != nil {
+		t.Fatalf("Tx: %v", err)
+	}
+	if buf[0] != 0xDE || buf[1] != 0xAD {
+		t.Fatalf("got %X, want DEAD", buf)
+	}
+
+	if err := bus.Tx(0x60, []byte{0x02}, buf); err == nil {
+		t.Fatal("expected error for an unmatched command")
+	}
+}
+
+func TestBusCallsRecorded(t *testing.T) {
+	bus := NewBus()
+	bus.Register(NewDevice8(0x40))
+
+	bus.WriteRegU8(0x40, 0x00, 0x01)
+	bus.ReadRegU8(0x40, 0x00)
+
+	calls := bus.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("got %d calls, want 2", len(calls))
+	}
+	if calls[0].Addr != 0x40 || calls[1].Addr != 0x40 {
+		t.Fatalf("got calls %+v, want both addressed to 0x40", calls)
+	}
+}
+
+func TestBusTransferAndTxTen(t *testing.T) {
+	bus := NewBus()
+	bus.Register(NewDevice8(0x40))
+
+	msgs := []i2c.Msg{
+		{Addr: 0x40, Buf: []byte{0x02, 0x55}},
+		{Addr: 0x40, Flags: i2c.MsgRead, Buf: []byte{0x02}},
+	}
+	if err := bus.Transfer(msgs); err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	if msgs[1].Buf[0] != 0x55 {
+		t.Fatalf("got 0x%02X, want 0x55", msgs[1].Buf[0])
+	}
+
+	if err := bus.TxTen(0x40, []byte{0x02}, make([]byte, 1)); err != nil {
+		t.Fatalf("TxTen: %v", err)
+	}
+}
+
+func TestBusFunctionality(t *testing.T) {
+	bus := NewBus()
+
+	funcs, err := bus.Functionality()
+	if err != nil {
+		t.Fatalf("Functionality: %v", err)
+	}
+	if funcs&i2c.FuncI2C == 0 {
+		t.Fatal("expected mock bus to report FuncI2C support")
+	}
+}
@@ -0,0 +1,249 @@
+package tester
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// Device is a fake i2c peripheral that can be registered with a Bus.
+type Device interface {
+	// Addr returns the 7-bit address the device responds to.
+	Addr() uint8
+	// ReadRegister reads len(buf) bytes starting at reg into buf.
+	ReadRegister(reg uint8, buf []byte) error
+	// WriteRegister writes buf starting at reg.
+	WriteRegister(reg uint8, buf []byte) error
+	// Tx handles a combined write/read transaction, as submitted via
+	// i2c.Bus.Tx. Either w or r may be nil.
+	Tx(w, r []byte) error
+}
+
+// Device8 is a Device fake backed by a flat, auto-incrementing 8-bit
+// register map, suitable for simple sensors such as the BMP280 or
+// MPU-series.
+type Device8 struct {
+	addr uint8
+
+	mtx  sync.Mutex
+	regs [256]byte
+}
+
+// NewDevice8 creates a Device8 responding at addr with all registers
+// initialized to zero.
+func NewDevice8(addr uint8) *Device8 {
+	return &Device8{addr: addr}
+}
+
+func (d *Device8) Addr() uint8 {
+	return d.addr
+}
+
+func (d *Device8) ReadRegister(reg uint8, buf []byte) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for i := range buf {
+		buf[i] = d.regs[uint8(int(reg)+i)]
+	}
+	return nil
+}
+
+func (d *Device8) WriteRegister(reg uint8, buf []byte) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for i, b := range buf {
+		d.regs[uint8(int(reg)+i)] = b
+	}
+	return nil
+}
+
+// Tx treats w[0] as the register pointer, optionally writes w[1:] to it,
+// then reads len(r) bytes starting from the same pointer.
+func (d *Device8) Tx(w, r []byte) error {
+	if len(w) == 0 {
+		return fmt.Errorf("tester: Device8 at 0x%02X requires a register address in w", d.addr)
+	}
+
+	reg := w[0]
+	if len(w) > 1 {
+		if err := d.WriteRegister(reg, w[1:]); err != nil {
+			return err
+		}
+	}
+	if len(r) == 0 {
+		return nil
+	}
+	return d.ReadRegister(reg, r)
+}
+
+// Set seeds register reg with value, for test setup.
+func (d *Device8) Set(reg uint8, value byte) {
+	d.WriteRegister(reg, []byte{value})
+}
+
+// Get returns the current value of register reg, for assertions.
+func (d *Device8) Get(reg uint8) byte {
+	var buf [1]byte
+	d.ReadRegister(reg, buf[:])
+	return buf[0]
+}
+
+// Device16 is a Device fake addressed with a 16-bit register pointer, for
+// fakes such as EEPROMs that take a two byte address in the write before
+// the repeated-START read.
+type Device16 struct {
+	addr uint8
+
+	mtx sync.Mutex
+	mem map[uint16]byte
+}
+
+// NewDevice16 creates a Device16 responding at addr with all registers
+// initialized to zero.
+func NewDevice16(addr uint8) *Device16 {
+	return &Device16{addr: addr, mem: make(map[uint16]byte)}
+}
+
+func (d *Device16) Addr() uint8 {
+	return d.addr
+}
+
+// ReadRegister treats reg as the low byte of a 16-bit pointer with a zero
+// high byte. Drivers addressing the full 16-bit space should use Tx.
+func (d *Device16) ReadRegister(reg uint8, buf []byte) error {
+	return d.readAt(uint16(reg), buf)
+}
+
+// WriteRegister treats reg as the low byte of a 16-bit pointer with a
+// zero high byte. Drivers addressing the full 16-bit space should use Tx.
+func (d *Device16) WriteRegister(reg uint8, buf []byte) error {
+	return d.writeAt(uint16(reg), buf)
+}
+
+// Tx treats w[0:2] as a big endian 16-bit register pointer, optionally
+// writes w[2:] to it, then reads len(r) bytes starting from the pointer.
+func (d *Device16) Tx(w, r []byte) error {
+	if len(w) < 2 {
+		return fmt.Errorf("tester: Device16 at 0x%02X requires a 2 byte register address in w", d.addr)
+	}
+
+	reg := uint16(w[0])<<8 | uint16(w[1])
+	if len(w) > 2 {
+		if err := d.writeAt(reg, w[2:]); err != nil {
+			return err
+		}
+	}
+	if len(r) == 0 {
+		return nil
+	}
+	return d.readAt(reg, r)
+}
+
+func (d *Device16) readAt(reg uint16, buf []byte) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for i := range buf {
+		buf[i] = d.mem[reg+uint16(i)]
+	}
+	return nil
+}
+
+func (d *Device16) writeAt(reg uint16, buf []byte) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for i, b := range buf {
+		d.mem[reg+uint16(i)] = b
+	}
+	return nil
+}
+
+// Set seeds register reg with value, for test setup.
+func (d *Device16) Set(reg uint16, value byte) {
+	d.writeAt(reg, []byte{value})
+}
+
+// Get returns the current value of register reg, for assertions.
+func (d *Device16) Get(reg uint16) byte {
+	var buf [1]byte
+	d.readAt(reg, buf[:])
+	return buf[0]
+}
+
+// Handler replies with Response when an incoming write exactly matches
+// Pattern.
+type Handler struct {
+	Pattern  []byte
+	Response []byte
+}
+
+// CommandDevice is a Device fake for peripherals that don't fit a simple
+// register model, where a write payload selects a command and the
+// following read returns a canned response.
+type CommandDevice struct {
+	addr uint8
+
+	mtx      sync.Mutex
+	handlers []Handler
+	pending  []byte
+}
+
+// NewCommandDevice creates a CommandDevice responding at addr with no
+// handlers registered.
+func NewCommandDevice(addr uint8) *CommandDevice {
+	return &CommandDevice{addr: addr}
+}
+
+func (d *CommandDevice) Addr() uint8 {
+	return d.addr
+}
+
+// Handle registers response to be returned by the read that follows a
+// write matching pattern exactly.
+func (d *CommandDevice) Handle(pattern, response []byte) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.handlers = append(d.handlers, Handler{Pattern: pattern, Response: response})
+}
+
+func (d *CommandDevice) Tx(w, r []byte) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if len(w) > 0 {
+		matched := false
+		for _, h := range d.handlers {
+			if bytes.Equal(h.Pattern, w) {
+				d.pending = h.Response
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("tester: CommandDevice at 0x%02X has no handler for write %X", d.addr, w)
+		}
+	}
+	if len(r) == 0 {
+		return nil
+	}
+
+	n := copy(r, d.pending)
+	for ; n < len(r); n++ {
+		r[n] = 0
+	}
+	return nil
+}
+
+// ReadRegister is equivalent to Tx([]byte{reg}, buf).
+func (d *CommandDevice) ReadRegister(reg uint8, buf []byte) error {
+	return d.Tx([]byte{reg}, buf)
+}
+
+// WriteRegister is equivalent to Tx(append([]byte{reg}, buf...), nil).
+func (d *CommandDevice) WriteRegister(reg uint8, buf []byte) error {
+	return d.Tx(append([]byte{reg}, buf...), nil)
+}